@@ -0,0 +1,131 @@
+package md5
+
+import (
+	"bufio"
+	"errors"
+	"hash"
+	"io"
+)
+
+const checkpointMagic = "ckpt"
+
+// checkpointing wraps a Digest and periodically emits its marshaled state
+// to sink, so a caller hashing a long stream doesn't have to call
+// MarshalBinary itself between writes.
+type checkpointing struct {
+	d     Digest
+	sink  io.Writer
+	every int64
+
+	sinceCheckpoint int64
+	err             error // sticky error from the most recent failed checkpoint write
+}
+
+// NewCheckpointing returns a hash.Hash that behaves like New, except that
+// every `every` bytes written it marshals its Digest state and emits the
+// frame to sink: a 4-byte magic, a big-endian uint32 payload length, and
+// the Digest.MarshalBinary payload (92 bytes for this package's Digest).
+// Pair it with ResumeCheckpointing to resume hashing a file after a crash
+// without rereading the already-hashed prefix.
+func NewCheckpointing(sink io.Writer, every int64) hash.Hash {
+	c := &checkpointing{sink: sink, every: every}
+	c.d.Reset()
+	return c
+}
+
+func (c *checkpointing) Size() int { return c.d.Size() }
+
+func (c *checkpointing) BlockSize() int { return c.d.BlockSize() }
+
+// Write never returns an error, matching the hash.Hash contract, even if a
+// checkpoint fails to reach sink; call Err to check for that.
+func (c *checkpointing) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		chunk := p
+		if left := c.every - c.sinceCheckpoint; c.every > 0 && int64(len(chunk)) > left {
+			chunk = chunk[:left]
+		}
+		c.d.Write(chunk)
+		c.sinceCheckpoint += int64(len(chunk))
+		p = p[len(chunk):]
+		if c.every > 0 && c.sinceCheckpoint >= c.every {
+			c.sinceCheckpoint = 0
+			if err := c.checkpoint(); err != nil {
+				c.err = err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (c *checkpointing) Sum(in []byte) []byte { return c.d.Sum(in) }
+
+func (c *checkpointing) Reset() {
+	c.d.Reset()
+	c.sinceCheckpoint = 0
+	c.err = nil
+}
+
+// Err returns the error from the most recent failed checkpoint write to
+// sink, if any. Hashing itself is unaffected by a failed checkpoint.
+func (c *checkpointing) Err() error { return c.err }
+
+func (c *checkpointing) checkpoint() error {
+	payload, err := c.d.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	frame := make([]byte, 0, len(checkpointMagic)+4+len(payload))
+	frame = append(frame, checkpointMagic...)
+	frame = appendUint32(frame, uint32(len(payload)))
+	frame = append(frame, payload...)
+	_, err = c.sink.Write(frame)
+	return err
+}
+
+// ResumeCheckpointing scans src for checkpoint frames written by a
+// checkpointing hash.Hash, seeds a new Digest from the most recent valid
+// one, and returns a hash.Hash ready to resume hashing plus the byte
+// offset into the original input the caller should resume feeding from.
+//
+// Frames are read in order and validated as they're read: a short read, a
+// bad magic, or a truncated payload (as happens when a crash lands
+// mid-write of the final frame) stops the scan and falls back to the last
+// fully-read frame, so a caller can always resume from the newest
+// consistent checkpoint even if the tail of the stream is garbage.
+func ResumeCheckpointing(src io.Reader, sink io.Writer, every int64) (hash.Hash, int64, error) {
+	br := bufio.NewReader(src)
+	var last []byte
+	for {
+		header := make([]byte, len(checkpointMagic)+4)
+		if _, err := io.ReadFull(br, header); err != nil {
+			break
+		}
+		if string(header[:len(checkpointMagic)]) != checkpointMagic {
+			break
+		}
+		_, length := consumeUint32(header[len(checkpointMagic):])
+		// Every frame this package writes carries a Digest.MarshalBinary
+		// payload, which is always exactly marshaledSize bytes. Reject
+		// anything else outright instead of trusting a corrupted length
+		// field enough to size an allocation from it.
+		if length != uint32(marshaledSize) {
+			break
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(br, payload); err != nil {
+			break
+		}
+		last = payload
+	}
+	if last == nil {
+		return nil, 0, errors.New("crypto/md5: no valid checkpoint frame found")
+	}
+
+	c := &checkpointing{sink: sink, every: every}
+	if err := c.d.UnmarshalBinary(last); err != nil {
+		return nil, 0, err
+	}
+	return c, int64(c.d.Len), nil
+}
@@ -0,0 +1,78 @@
+package md5
+
+import (
+	"bytes"
+	cryptomd5 "crypto/md5"
+	"testing"
+)
+
+func TestCheckpointingMatchesPlainSum(t *testing.T) {
+	data := bytes.Repeat([]byte("checkpoint payload "), 100)
+
+	var sink bytes.Buffer
+	c := NewCheckpointing(&sink, 37)
+	c.Write(data)
+
+	want := cryptomd5.Sum(data)
+	if got := c.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Fatalf("Sum = %x, want %x", got, want)
+	}
+	if sink.Len() == 0 {
+		t.Fatal("expected at least one checkpoint frame to be emitted")
+	}
+}
+
+func TestResumeCheckpointingAfterInterruption(t *testing.T) {
+	data := bytes.Repeat([]byte("resume me please "), 200)
+	const every = 101
+	crashAt := len(data) * 2 / 3
+
+	var sink bytes.Buffer
+	c := NewCheckpointing(&sink, every)
+	c.Write(data[:crashAt])
+
+	// Simulate a crash: the in-memory hasher is discarded, but the
+	// checkpoint stream written so far survives.
+	h, offset, err := ResumeCheckpointing(bytes.NewReader(sink.Bytes()), &sink, every)
+	if err != nil {
+		t.Fatalf("ResumeCheckpointing: %v", err)
+	}
+	if offset == 0 || offset > int64(crashAt) {
+		t.Fatalf("resume offset = %d, want in (0, %d]", offset, crashAt)
+	}
+
+	h.Write(data[offset:])
+	want := cryptomd5.Sum(data)
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Fatalf("resumed Sum = %x, want %x", got, want)
+	}
+}
+
+func TestResumeCheckpointingSkipsTruncatedFrame(t *testing.T) {
+	data := bytes.Repeat([]byte("truncated tail "), 100)
+	const every = 40
+
+	var sink bytes.Buffer
+	c := NewCheckpointing(&sink, every)
+	c.Write(data)
+
+	good := append([]byte(nil), sink.Bytes()...)
+	corrupted := append(good, []byte("not a real frame, just noise")...)
+
+	h, offset, err := ResumeCheckpointing(bytes.NewReader(corrupted), &sink, every)
+	if err != nil {
+		t.Fatalf("ResumeCheckpointing: %v", err)
+	}
+
+	h.Write(data[offset:])
+	want := cryptomd5.Sum(data)
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Fatalf("resumed Sum = %x, want %x", got, want)
+	}
+}
+
+func TestResumeCheckpointingNoFramesErrors(t *testing.T) {
+	if _, _, err := ResumeCheckpointing(bytes.NewReader(nil), new(bytes.Buffer), 10); err == nil {
+		t.Fatal("expected an error when no checkpoint frames are present")
+	}
+}
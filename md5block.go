@@ -0,0 +1,136 @@
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// MD5 block step.
+// In its own file so that a faster assembly or C version
+// can be substituted easily.
+
+package md5
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// haveAsm reports whether an architecture-specific block implementation is
+// wired up in place of blockGeneric. This fork ships no assembly, so the
+// generic path is always used.
+const haveAsm = false
+
+func block(d *Digest, p []byte) {
+	blockGeneric(d, p)
+}
+
+func blockGeneric(d *Digest, p []byte) {
+	// load state
+	a, b, c, dd := d.S[0], d.S[1], d.S[2], d.S[3]
+
+	for i := 0; i <= len(p)-BlockSize; i += BlockSize {
+		// eliminate bounds checks on q
+		q := p[i:]
+		q = q[:BlockSize:BlockSize]
+
+		// save current state
+		aa, bb, cc, ddd := a, b, c, dd
+
+		// load the 16 message words once, up front
+		x0 := binary.LittleEndian.Uint32(q[4*0x0:])
+		x1 := binary.LittleEndian.Uint32(q[4*0x1:])
+		x2 := binary.LittleEndian.Uint32(q[4*0x2:])
+		x3 := binary.LittleEndian.Uint32(q[4*0x3:])
+		x4 := binary.LittleEndian.Uint32(q[4*0x4:])
+		x5 := binary.LittleEndian.Uint32(q[4*0x5:])
+		x6 := binary.LittleEndian.Uint32(q[4*0x6:])
+		x7 := binary.LittleEndian.Uint32(q[4*0x7:])
+		x8 := binary.LittleEndian.Uint32(q[4*0x8:])
+		x9 := binary.LittleEndian.Uint32(q[4*0x9:])
+		xa := binary.LittleEndian.Uint32(q[4*0xa:])
+		xb := binary.LittleEndian.Uint32(q[4*0xb:])
+		xc := binary.LittleEndian.Uint32(q[4*0xc:])
+		xd := binary.LittleEndian.Uint32(q[4*0xd:])
+		xe := binary.LittleEndian.Uint32(q[4*0xe:])
+		xf := binary.LittleEndian.Uint32(q[4*0xf:])
+
+		// round 1
+		a = b + bits.RotateLeft32((((c^dd)&b)^dd)+a+x0+0xd76aa478, 7)
+		dd = a + bits.RotateLeft32((((b^c)&a)^c)+dd+x1+0xe8c7b756, 12)
+		c = dd + bits.RotateLeft32((((a^b)&dd)^b)+c+x2+0x242070db, 17)
+		b = c + bits.RotateLeft32((((dd^a)&c)^a)+b+x3+0xc1bdceee, 22)
+		a = b + bits.RotateLeft32((((c^dd)&b)^dd)+a+x4+0xf57c0faf, 7)
+		dd = a + bits.RotateLeft32((((b^c)&a)^c)+dd+x5+0x4787c62a, 12)
+		c = dd + bits.RotateLeft32((((a^b)&dd)^b)+c+x6+0xa8304613, 17)
+		b = c + bits.RotateLeft32((((dd^a)&c)^a)+b+x7+0xfd469501, 22)
+		a = b + bits.RotateLeft32((((c^dd)&b)^dd)+a+x8+0x698098d8, 7)
+		dd = a + bits.RotateLeft32((((b^c)&a)^c)+dd+x9+0x8b44f7af, 12)
+		c = dd + bits.RotateLeft32((((a^b)&dd)^b)+c+xa+0xffff5bb1, 17)
+		b = c + bits.RotateLeft32((((dd^a)&c)^a)+b+xb+0x895cd7be, 22)
+		a = b + bits.RotateLeft32((((c^dd)&b)^dd)+a+xc+0x6b901122, 7)
+		dd = a + bits.RotateLeft32((((b^c)&a)^c)+dd+xd+0xfd987193, 12)
+		c = dd + bits.RotateLeft32((((a^b)&dd)^b)+c+xe+0xa679438e, 17)
+		b = c + bits.RotateLeft32((((dd^a)&c)^a)+b+xf+0x49b40821, 22)
+
+		// round 2
+		a = b + bits.RotateLeft32((((b^c)&dd)^c)+a+x1+0xf61e2562, 5)
+		dd = a + bits.RotateLeft32((((a^b)&c)^b)+dd+x6+0xc040b340, 9)
+		c = dd + bits.RotateLeft32((((dd^a)&b)^a)+c+xb+0x265e5a51, 14)
+		b = c + bits.RotateLeft32((((c^dd)&a)^dd)+b+x0+0xe9b6c7aa, 20)
+		a = b + bits.RotateLeft32((((b^c)&dd)^c)+a+x5+0xd62f105d, 5)
+		dd = a + bits.RotateLeft32((((a^b)&c)^b)+dd+xa+0x02441453, 9)
+		c = dd + bits.RotateLeft32((((dd^a)&b)^a)+c+xf+0xd8a1e681, 14)
+		b = c + bits.RotateLeft32((((c^dd)&a)^dd)+b+x4+0xe7d3fbc8, 20)
+		a = b + bits.RotateLeft32((((b^c)&dd)^c)+a+x9+0x21e1cde6, 5)
+		dd = a + bits.RotateLeft32((((a^b)&c)^b)+dd+xe+0xc33707d6, 9)
+		c = dd + bits.RotateLeft32((((dd^a)&b)^a)+c+x3+0xf4d50d87, 14)
+		b = c + bits.RotateLeft32((((c^dd)&a)^dd)+b+x8+0x455a14ed, 20)
+		a = b + bits.RotateLeft32((((b^c)&dd)^c)+a+xd+0xa9e3e905, 5)
+		dd = a + bits.RotateLeft32((((a^b)&c)^b)+dd+x2+0xfcefa3f8, 9)
+		c = dd + bits.RotateLeft32((((dd^a)&b)^a)+c+x7+0x676f02d9, 14)
+		b = c + bits.RotateLeft32((((c^dd)&a)^dd)+b+xc+0x8d2a4c8a, 20)
+
+		// round 3
+		a = b + bits.RotateLeft32((b^c^dd)+a+x5+0xfffa3942, 4)
+		dd = a + bits.RotateLeft32((a^b^c)+dd+x8+0x8771f681, 11)
+		c = dd + bits.RotateLeft32((dd^a^b)+c+xb+0x6d9d6122, 16)
+		b = c + bits.RotateLeft32((c^dd^a)+b+xe+0xfde5380c, 23)
+		a = b + bits.RotateLeft32((b^c^dd)+a+x1+0xa4beea44, 4)
+		dd = a + bits.RotateLeft32((a^b^c)+dd+x4+0x4bdecfa9, 11)
+		c = dd + bits.RotateLeft32((dd^a^b)+c+x7+0xf6bb4b60, 16)
+		b = c + bits.RotateLeft32((c^dd^a)+b+xa+0xbebfbc70, 23)
+		a = b + bits.RotateLeft32((b^c^dd)+a+xd+0x289b7ec6, 4)
+		dd = a + bits.RotateLeft32((a^b^c)+dd+x0+0xeaa127fa, 11)
+		c = dd + bits.RotateLeft32((dd^a^b)+c+x3+0xd4ef3085, 16)
+		b = c + bits.RotateLeft32((c^dd^a)+b+x6+0x04881d05, 23)
+		a = b + bits.RotateLeft32((b^c^dd)+a+x9+0xd9d4d039, 4)
+		dd = a + bits.RotateLeft32((a^b^c)+dd+xc+0xe6db99e5, 11)
+		c = dd + bits.RotateLeft32((dd^a^b)+c+xf+0x1fa27cf8, 16)
+		b = c + bits.RotateLeft32((c^dd^a)+b+x2+0xc4ac5665, 23)
+
+		// round 4
+		a = b + bits.RotateLeft32((c^(b|^dd))+a+x0+0xf4292244, 6)
+		dd = a + bits.RotateLeft32((b^(a|^c))+dd+x7+0x432aff97, 10)
+		c = dd + bits.RotateLeft32((a^(dd|^b))+c+xe+0xab9423a7, 15)
+		b = c + bits.RotateLeft32((dd^(c|^a))+b+x5+0xfc93a039, 21)
+		a = b + bits.RotateLeft32((c^(b|^dd))+a+xc+0x655b59c3, 6)
+		dd = a + bits.RotateLeft32((b^(a|^c))+dd+x3+0x8f0ccc92, 10)
+		c = dd + bits.RotateLeft32((a^(dd|^b))+c+xa+0xffeff47d, 15)
+		b = c + bits.RotateLeft32((dd^(c|^a))+b+x1+0x85845dd1, 21)
+		a = b + bits.RotateLeft32((c^(b|^dd))+a+x8+0x6fa87e4f, 6)
+		dd = a + bits.RotateLeft32((b^(a|^c))+dd+xf+0xfe2ce6e0, 10)
+		c = dd + bits.RotateLeft32((a^(dd|^b))+c+x6+0xa3014314, 15)
+		b = c + bits.RotateLeft32((dd^(c|^a))+b+xd+0x4e0811a1, 21)
+		a = b + bits.RotateLeft32((c^(b|^dd))+a+x4+0xf7537e82, 6)
+		dd = a + bits.RotateLeft32((b^(a|^c))+dd+xb+0xbd3af235, 10)
+		c = dd + bits.RotateLeft32((a^(dd|^b))+c+x2+0x2ad7d2bb, 15)
+		b = c + bits.RotateLeft32((dd^(c|^a))+b+x9+0xeb86d391, 21)
+
+		// add saved state
+		a += aa
+		b += bb
+		c += cc
+		dd += ddd
+	}
+
+	// save state
+	d.S[0], d.S[1], d.S[2], d.S[3] = a, b, c, dd
+}
@@ -0,0 +1,50 @@
+package md5
+
+import (
+	"testing"
+	"unsafe"
+)
+
+var benchBuf = make([]byte, 8192+1)
+var benchSum = make([]byte, Size)
+
+func benchmarkBlockSize(b *testing.B, size int, unaligned bool) {
+	buf := benchBuf
+	if unaligned {
+		if uintptr(unsafe.Pointer(&buf[0]))&(unsafe.Alignof(uint32(0))-1) == 0 {
+			buf = buf[1:]
+		}
+	}
+	d := New()
+	b.SetBytes(int64(size))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.Reset()
+		d.Write(buf[:size])
+		d.Sum(benchSum[:0])
+	}
+}
+
+func BenchmarkHash8Bytes(b *testing.B) {
+	benchmarkBlockSize(b, 8, false)
+}
+
+func BenchmarkHash8BytesUnaligned(b *testing.B) {
+	benchmarkBlockSize(b, 8, true)
+}
+
+func BenchmarkHash1K(b *testing.B) {
+	benchmarkBlockSize(b, 1024, false)
+}
+
+func BenchmarkHash1KUnaligned(b *testing.B) {
+	benchmarkBlockSize(b, 1024, true)
+}
+
+func BenchmarkHash8K(b *testing.B) {
+	benchmarkBlockSize(b, 8192, false)
+}
+
+func BenchmarkHash8KUnaligned(b *testing.B) {
+	benchmarkBlockSize(b, 8192, true)
+}
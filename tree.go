@@ -0,0 +1,232 @@
+package md5
+
+import (
+	"errors"
+	"hash"
+	"runtime"
+	"sync"
+)
+
+// DefaultLeafSize is the leaf size NewTree uses when given leafSize <= 0.
+const DefaultLeafSize = 1 << 20 // 1 MiB
+
+// Tree hashes large inputs in parallel. Input is split into fixed-size
+// leaves; each leaf is hashed independently with its own Digest, and the
+// resulting leaf checksums are folded pairwise into a binary Merkle tree
+// whose interior nodes are md5.Sum(left || right).
+//
+// Tree is not an MD5 of the concatenated input: once the input spans more
+// than one leaf, the same bytes hashed with a different leaf size, or with
+// plain Sum, do not produce the same result. Input that fits in a single
+// leaf (including empty input) yields exactly Sum of that input. Tree exists for reproducible parallel hashing in
+// content-addressed storage pipelines, not as a drop-in replacement for
+// New.
+type Tree struct {
+	leafSize    int
+	parallelism int
+
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	sem     chan struct{}
+	results [][Size]byte // completed leaf hashes, indexed by leaf number
+	cur     []byte       // bytes buffered for the leaf currently being filled
+	leafNum int          // index of the leaf currently being filled
+}
+
+// NewTree returns a hash.Hash that hashes its input as a Tree, splitting it
+// into leaves of leafSize bytes (DefaultLeafSize if leafSize <= 0) and
+// hashing up to parallelism leaves concurrently (runtime.GOMAXPROCS(0) if
+// parallelism <= 0).
+func NewTree(leafSize, parallelism int) hash.Hash {
+	if leafSize <= 0 {
+		leafSize = DefaultLeafSize
+	}
+	if parallelism <= 0 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	return &Tree{
+		leafSize:    leafSize,
+		parallelism: parallelism,
+		sem:         make(chan struct{}, parallelism),
+		cur:         make([]byte, 0, leafSize),
+	}
+}
+
+// TreeRoot folds pre-computed leaf checksums into the same binary Merkle
+// tree that Tree builds internally, for offline verification of a leaf set
+// hashed elsewhere (e.g. leaves computed on different machines). leafSize
+// is the leaf size the leaves were hashed with; it does not affect the
+// fold but documents which Tree a given leaf set belongs to.
+//
+// At each level, nodes are paired left-to-right and combined via
+// md5.Sum(left || right); if a level has an odd node out, it is carried
+// up unchanged to be paired at the next level instead of being duplicated.
+func TreeRoot(leafSize int, leaves [][Size]byte) [Size]byte {
+	if len(leaves) == 0 {
+		return Sum(nil)
+	}
+	level := leaves
+	for len(level) > 1 {
+		next := make([][Size]byte, 0, (len(level)+1)/2)
+		for i := 0; i+1 < len(level); i += 2 {
+			var pair [2 * Size]byte
+			copy(pair[:Size], level[i][:])
+			copy(pair[Size:], level[i+1][:])
+			next = append(next, Sum(pair[:]))
+		}
+		if len(level)%2 == 1 {
+			next = append(next, level[len(level)-1])
+		}
+		level = next
+	}
+	return level[0]
+}
+
+func (t *Tree) Size() int { return Size }
+
+func (t *Tree) BlockSize() int { return t.leafSize }
+
+// ready reports whether t was produced by NewTree or populated by a
+// successful UnmarshalBinary. A zero-value Tree has a nil t.sem and a
+// leafSize of 0, which would otherwise make Write's "leaf is full" check
+// true on the very first byte and dispatch block forever sending on that
+// nil channel.
+func (t *Tree) ready() bool { return t.sem != nil && t.leafSize > 0 }
+
+func (t *Tree) Write(p []byte) (int, error) {
+	if !t.ready() {
+		panic("md5: Tree.Write called before NewTree or a successful UnmarshalBinary")
+	}
+	n := len(p)
+	for len(p) > 0 {
+		free := t.leafSize - len(t.cur)
+		take := len(p)
+		if take > free {
+			take = free
+		}
+		t.cur = append(t.cur, p[:take]...)
+		p = p[take:]
+		if len(t.cur) == t.leafSize {
+			t.dispatch(t.leafNum, t.cur)
+			t.cur = make([]byte, 0, t.leafSize)
+			t.leafNum++
+		}
+	}
+	return n, nil
+}
+
+// dispatch hashes leaf on a worker goroutine, bounded to t.parallelism
+// concurrent leaves, and stores the result at index idx once done.
+func (t *Tree) dispatch(idx int, leaf []byte) {
+	t.sem <- struct{}{}
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		defer func() { <-t.sem }()
+
+		var d Digest
+		d.Reset()
+		d.Write(leaf)
+		sum := d.checkSum()
+
+		t.mu.Lock()
+		for len(t.results) <= idx {
+			t.results = append(t.results, [Size]byte{})
+		}
+		t.results[idx] = sum
+		t.mu.Unlock()
+	}()
+}
+
+// Sum appends the Merkle root to in without mutating t, so the caller can
+// keep writing and summing, matching Digest.Sum.
+func (t *Tree) Sum(in []byte) []byte {
+	t.wg.Wait()
+
+	t.mu.Lock()
+	leaves := append([][Size]byte(nil), t.results...)
+	tail := append([]byte(nil), t.cur...)
+	t.mu.Unlock()
+
+	if len(tail) > 0 {
+		var d Digest
+		d.Reset()
+		d.Write(tail)
+		leaves = append(leaves, d.checkSum())
+	}
+
+	root := TreeRoot(t.leafSize, leaves)
+	return append(in, root[:]...)
+}
+
+func (t *Tree) Reset() {
+	t.wg.Wait()
+	t.mu.Lock()
+	t.results = t.results[:0]
+	t.cur = make([]byte, 0, t.leafSize)
+	t.leafNum = 0
+	t.mu.Unlock()
+}
+
+const treeMagic = "mdt1"
+
+// MarshalBinary serializes the completed leaf checksums and the bytes
+// buffered for the leaf still being filled, so a long-running job can
+// checkpoint and resume a Tree across process restarts, mirroring what
+// Digest.MarshalBinary does for a single digest.
+func (t *Tree) MarshalBinary() ([]byte, error) {
+	t.wg.Wait()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b := make([]byte, 0, len(treeMagic)+12+len(t.results)*Size+4+len(t.cur))
+	b = append(b, treeMagic...)
+	b = appendUint32(b, uint32(t.leafSize))
+	b = appendUint32(b, uint32(t.parallelism))
+	b = appendUint32(b, uint32(len(t.results)))
+	for _, leaf := range t.results {
+		b = append(b, leaf[:]...)
+	}
+	b = appendUint32(b, uint32(len(t.cur)))
+	b = append(b, t.cur...)
+	return b, nil
+}
+
+func (t *Tree) UnmarshalBinary(b []byte) error {
+	if len(b) < len(treeMagic) || string(b[:len(treeMagic)]) != treeMagic {
+		return errors.New("crypto/md5: invalid tree state identifier")
+	}
+	b = b[len(treeMagic):]
+	if len(b) < 12 {
+		return errors.New("crypto/md5: invalid tree state size")
+	}
+	var leafSize, parallelism, n uint32
+	b, leafSize = consumeUint32(b)
+	b, parallelism = consumeUint32(b)
+	b, n = consumeUint32(b)
+	if uint64(len(b)) < uint64(n)*Size+4 {
+		return errors.New("crypto/md5: invalid tree state size")
+	}
+	results := make([][Size]byte, n)
+	for i := range results {
+		copy(results[i][:], b[:Size])
+		b = b[Size:]
+	}
+	var curLen uint32
+	b, curLen = consumeUint32(b)
+	if uint64(len(b)) != uint64(curLen) {
+		return errors.New("crypto/md5: invalid tree state size")
+	}
+
+	if parallelism == 0 {
+		parallelism = uint32(runtime.GOMAXPROCS(0))
+	}
+	t.leafSize = int(leafSize)
+	t.parallelism = int(parallelism)
+	t.sem = make(chan struct{}, t.parallelism)
+	t.results = results
+	t.leafNum = len(results)
+	t.cur = append(make([]byte, 0, t.leafSize), b...)
+	return nil
+}
@@ -0,0 +1,103 @@
+package md5
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestTreeMatchesManualFold(t *testing.T) {
+	const leafSize = 16
+	data := bytes.Repeat([]byte("0123456789abcdef"), 10) // 10 full leaves, no tail
+
+	tr := NewTree(leafSize, 4)
+	tr.Write(data)
+	got := tr.Sum(nil)
+
+	var want [][Size]byte
+	for i := 0; i < len(data); i += leafSize {
+		want = append(want, Sum(data[i:i+leafSize]))
+	}
+	root := TreeRoot(leafSize, want)
+
+	if !bytes.Equal(got, root[:]) {
+		t.Fatalf("Tree.Sum = %x, want %x", got, root)
+	}
+}
+
+func TestTreeHandlesPartialTrailingLeaf(t *testing.T) {
+	const leafSize = 16
+	data := bytes.Repeat([]byte("x"), leafSize*3+5)
+
+	tr := NewTree(leafSize, 2)
+	tr.Write(data)
+	got := tr.Sum(nil)
+
+	var want [][Size]byte
+	for i := 0; i < len(data); i += leafSize {
+		end := i + leafSize
+		if end > len(data) {
+			end = len(data)
+		}
+		want = append(want, Sum(data[i:end]))
+	}
+	root := TreeRoot(leafSize, want)
+
+	if !bytes.Equal(got, root[:]) {
+		t.Fatalf("Tree.Sum = %x, want %x", got, root)
+	}
+}
+
+func TestTreeMarshalRoundTrip(t *testing.T) {
+	const leafSize = 16
+	data := bytes.Repeat([]byte("y"), leafSize*3+7)
+
+	tr := NewTree(leafSize, 2).(*Tree)
+	tr.Write(data[:leafSize*2+3])
+
+	b, err := tr.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	resumed := &Tree{}
+	if err := resumed.UnmarshalBinary(b); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	resumed.Write(data[leafSize*2+3:])
+	got := resumed.Sum(nil)
+
+	tr2 := NewTree(leafSize, 2)
+	tr2.Write(data)
+	want := tr2.Sum(nil)
+
+	if !bytes.Equal(got, want) {
+		t.Fatalf("resumed Tree.Sum = %x, want %x", got, want)
+	}
+}
+
+func TestTreeRootEmpty(t *testing.T) {
+	root := TreeRoot(16, nil)
+	if root != Sum(nil) {
+		t.Fatalf("TreeRoot(nil) = %x, want %x", root, Sum(nil))
+	}
+}
+
+func TestTreeZeroValueWritePanicsInsteadOfHanging(t *testing.T) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if recover() == nil {
+				t.Error("Write on a zero-value Tree: expected a panic, got none")
+			}
+		}()
+		(&Tree{}).Write([]byte("hello"))
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Write on a zero-value Tree hung instead of panicking")
+	}
+}
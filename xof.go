@@ -0,0 +1,147 @@
+package md5
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// InfiniteLength, passed as outLen to NewXOF, requests an XOF with no
+// fixed output length: Read never returns io.EOF.
+const InfiniteLength = 0xFFFFFFFF
+
+// XOF extends MD5 into a variable-length output generator using the MGF1
+// construction from PKCS#1: the output is Sum(seed||BE32(0)),
+// Sum(seed||BE32(1)), Sum(seed||BE32(2)), ... concatenated and truncated
+// to outLen bytes.
+//
+// MD5's collision weakness carries through MGF1 unchanged: XOF is meant
+// for non-security uses such as deterministic padding, test fixtures, or
+// content-defined chunking seeds, not as a secure mask generation function
+// or KDF.
+type XOF struct {
+	Seed      []byte
+	Counter   uint32
+	Buf       [Size]byte
+	Pos       int    // next unread byte of Buf; Pos == Size means Buf is exhausted
+	Remaining uint32 // bytes left to emit; meaningless when Infinite
+	Infinite  bool
+}
+
+// NewXOF returns an io.Reader that emits outLen bytes derived from seed
+// via MGF1. Pass InfiniteLength for an unbounded reader. The returned
+// value also implements ReadAll plus encoding.BinaryMarshaler and
+// encoding.BinaryUnmarshaler, so a caller can checkpoint and resume a
+// long-running read the same way it can with Digest.
+func NewXOF(seed []byte, outLen uint32) io.Reader {
+	return &XOF{
+		Seed:      append([]byte(nil), seed...),
+		Remaining: outLen,
+		Infinite:  outLen == InfiniteLength,
+		Pos:       Size,
+	}
+}
+
+func (x *XOF) fill() {
+	var ctr [4]byte
+	binary.BigEndian.PutUint32(ctr[:], x.Counter)
+	x.Counter++
+	x.Buf = Sum(append(append([]byte(nil), x.Seed...), ctr[:]...))
+	x.Pos = 0
+}
+
+func (x *XOF) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) && (x.Infinite || x.Remaining > 0) {
+		if x.Pos == Size {
+			x.fill()
+		}
+		take := Size - x.Pos
+		if take > len(p)-n {
+			take = len(p) - n
+		}
+		if !x.Infinite && uint32(take) > x.Remaining {
+			take = int(x.Remaining)
+		}
+		copy(p[n:n+take], x.Buf[x.Pos:x.Pos+take])
+		x.Pos += take
+		n += take
+		if !x.Infinite {
+			x.Remaining -= uint32(take)
+		}
+	}
+	if n == 0 && len(p) > 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+// ReadAll reads the remaining output of a finite XOF in one call.
+func (x *XOF) ReadAll() ([]byte, error) {
+	if x.Infinite {
+		return nil, errors.New("crypto/md5: ReadAll called on an infinite-length XOF")
+	}
+	out := make([]byte, x.Remaining)
+	if _, err := io.ReadFull(x, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+const (
+	xofMagic     = "mdx1"
+	xofFixedSize = 4 /* counter */ + Size /* buf */ + 4 /* pos */ + 4 /* remaining */ + 1 /* infinite */
+)
+
+// MarshalBinary serializes the XOF's counter, residual buffer, and
+// position so a caller can resume reading from exactly where it left off.
+func (x *XOF) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, len(xofMagic)+4+len(x.Seed)+xofFixedSize)
+	b = append(b, xofMagic...)
+	b = appendUint32(b, uint32(len(x.Seed)))
+	b = append(b, x.Seed...)
+	b = appendUint32(b, x.Counter)
+	b = append(b, x.Buf[:]...)
+	b = appendUint32(b, uint32(x.Pos))
+	b = appendUint32(b, x.Remaining)
+	if x.Infinite {
+		b = append(b, 1)
+	} else {
+		b = append(b, 0)
+	}
+	return b, nil
+}
+
+func (x *XOF) UnmarshalBinary(b []byte) error {
+	if len(b) < len(xofMagic) || string(b[:len(xofMagic)]) != xofMagic {
+		return errors.New("crypto/md5: invalid xof state identifier")
+	}
+	b = b[len(xofMagic):]
+	if len(b) < 4 {
+		return errors.New("crypto/md5: invalid xof state size")
+	}
+	var seedLen uint32
+	b, seedLen = consumeUint32(b)
+	if uint64(len(b)) < uint64(seedLen)+xofFixedSize {
+		return errors.New("crypto/md5: invalid xof state size")
+	}
+	seed := append([]byte(nil), b[:seedLen]...)
+	b = b[seedLen:]
+
+	var counter, pos, remaining uint32
+	b, counter = consumeUint32(b)
+	var buf [Size]byte
+	copy(buf[:], b[:Size])
+	b = b[Size:]
+	b, pos = consumeUint32(b)
+	b, remaining = consumeUint32(b)
+	infinite := b[0] != 0
+
+	x.Seed = seed
+	x.Counter = counter
+	x.Buf = buf
+	x.Pos = int(pos)
+	x.Remaining = remaining
+	x.Infinite = infinite
+	return nil
+}
@@ -0,0 +1,115 @@
+package md5
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+var xofTests = []struct {
+	seed   string
+	outLen uint32
+}{
+	{"", 0},
+	{"seed", 1},
+	{"seed", 15},
+	{"seed", 16},
+	{"seed", 17},
+	{"a longer seed value used for mgf1", 100},
+}
+
+func TestXOFDeterministic(t *testing.T) {
+	for _, tt := range xofTests {
+		out1, err := NewXOF([]byte(tt.seed), tt.outLen).(*XOF).ReadAll()
+		if err != nil {
+			t.Fatalf("seed %q: ReadAll: %v", tt.seed, err)
+		}
+		out2, err := NewXOF([]byte(tt.seed), tt.outLen).(*XOF).ReadAll()
+		if err != nil {
+			t.Fatalf("seed %q: ReadAll: %v", tt.seed, err)
+		}
+		if !bytes.Equal(out1, out2) {
+			t.Fatalf("seed %q: not deterministic: %x != %x", tt.seed, out1, out2)
+		}
+		if len(out1) != int(tt.outLen) {
+			t.Fatalf("seed %q: len = %d, want %d", tt.seed, len(out1), tt.outLen)
+		}
+	}
+}
+
+func TestXOFMatchesManualMGF1(t *testing.T) {
+	seed := []byte("seed")
+	const outLen = 50
+
+	var want []byte
+	for counter := uint32(0); len(want) < outLen; counter++ {
+		var ctr [4]byte
+		ctr[0] = byte(counter >> 24)
+		ctr[1] = byte(counter >> 16)
+		ctr[2] = byte(counter >> 8)
+		ctr[3] = byte(counter)
+		sum := Sum(append(append([]byte(nil), seed...), ctr[:]...))
+		want = append(want, sum[:]...)
+	}
+	want = want[:outLen]
+
+	got, err := NewXOF(seed, outLen).(*XOF).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("XOF output = %x, want %x", got, want)
+	}
+}
+
+func TestXOFResumeAcrossMarshal(t *testing.T) {
+	seed := []byte("checkpoint me")
+	const outLen = 200
+
+	x := NewXOF(seed, outLen).(*XOF)
+	first := make([]byte, 37)
+	if _, err := io.ReadFull(x, first); err != nil {
+		t.Fatalf("ReadFull: %v", err)
+	}
+
+	b, err := x.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	resumed := &XOF{}
+	if err := resumed.UnmarshalBinary(b); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	rest, err := resumed.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	full, err := NewXOF(seed, outLen).(*XOF).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	got := append(append([]byte(nil), first...), rest...)
+	if !bytes.Equal(got, full) {
+		t.Fatalf("resumed output = %x, want %x", got, full)
+	}
+}
+
+func TestXOFInfiniteNeverEOF(t *testing.T) {
+	x := NewXOF([]byte("seed"), InfiniteLength)
+	buf := make([]byte, Size*3+7)
+	for i := 0; i < 10; i++ {
+		if _, err := io.ReadFull(x, buf); err != nil {
+			t.Fatalf("ReadFull iteration %d: %v", i, err)
+		}
+	}
+}
+
+func TestXOFReadAllRejectsInfinite(t *testing.T) {
+	x := NewXOF([]byte("seed"), InfiniteLength).(*XOF)
+	if _, err := x.ReadAll(); err == nil {
+		t.Fatal("ReadAll on infinite XOF: got nil error, want error")
+	}
+}